@@ -18,6 +18,11 @@ import (
 type CheckResult struct {
 	State            pkg.CommitState
 	Summary, Details string
+
+	// Kind identifies what produced this check (e.g. "kubeconform",
+	// "preupgrade"), so a Renderer can pick a kind-specific layout for it.
+	// Empty means "use the default layout".
+	Kind string
 }
 
 type AppResults struct {
@@ -28,15 +33,17 @@ func (ar *AppResults) AddCheckResult(result CheckResult) {
 	ar.results = append(ar.results, result)
 }
 
-func NewMessage(name string, prId, commentId int, vcs toEmoji) *Message {
+func NewMessage(name string, prId, commentId int, renderer Renderer) *Message {
 	return &Message{
-		Name:    name,
-		CheckID: prId,
-		NoteID:  commentId,
-		vcs:     vcs,
+		Name:     name,
+		CheckID:  prId,
+		NoteID:   commentId,
+		renderer: renderer,
+		mode:     ModeAggregated,
 
 		apps:           make(map[string]*AppResults),
 		deletedAppsSet: make(map[string]struct{}),
+		appNoteIDs:     make(map[string]int),
 	}
 }
 
@@ -44,6 +51,31 @@ type toEmoji interface {
 	ToEmoji(state pkg.CommitState) string
 }
 
+// Mode selects how Message's comments are laid out: one aggregated comment
+// covering every app (ModeAggregated, the default), or one comment per app
+// plus a collapsed index comment linking out to each (ModePerApp).
+type Mode int
+
+const (
+	ModeAggregated Mode = iota
+	ModePerApp
+)
+
+// Commenter is the minimal VCS contract UpsertAppComment and RemoveAppComment
+// need to post, update, or delete a single comment.
+type Commenter interface {
+	// UpsertComment creates a new comment when noteID is zero, otherwise
+	// updates the existing one. It returns the comment's (possibly new) ID.
+	UpsertComment(ctx context.Context, noteID int, body string) (int, error)
+	DeleteComment(ctx context.Context, noteID int) error
+	// CommentURL returns the permalink for a previously posted comment
+	// (e.g. GitHub's "#issuecomment-<id>" or GitLab's "#note_<id>" fragment
+	// on the PR/MR URL), so the index comment can link to it correctly.
+	// kubechecks has no way to construct this itself without knowing both
+	// the PR/MR URL and which platform it's talking to.
+	CommentURL(noteID int) string
+}
+
 // Message type that allows concurrent updates
 // Has a reference to the owner/repo (ie zapier/kubechecks),
 // the PR/MR id, and the actual messsage
@@ -51,15 +83,54 @@ type Message struct {
 	Name    string
 	Owner   string
 	CheckID int
-	NoteID  int
+
+	// NoteID is the VCS comment ID for the single aggregated comment
+	// produced by BuildComment. It is only meaningful when callers use
+	// BuildComment (singular); once a caller switches to BuildComments
+	// (plural, see PartNoteIDs below), NoteID is stale and should be
+	// ignored - the two are mutually exclusive comment-splitting strategies
+	// for the same PR/MR, not a pair of comments posted together.
+	NoteID int
+
+	// PartNoteIDs holds the VCS comment ID for each part produced by the
+	// most recent BuildComments call, in part order, so the next run can
+	// update the matching comment instead of orphaning stale parts. It
+	// supersedes NoteID for callers that use BuildComments.
+	PartNoteIDs []int
 
 	// Key = Appname, value = Results
-	apps   map[string]*AppResults
-	footer string
-	lock   sync.Mutex
-	vcs    toEmoji
+	apps     map[string]*AppResults
+	footer   string
+	lock     sync.Mutex
+	renderer Renderer
 
 	deletedAppsSet map[string]struct{}
+
+	// ModePerApp fields: per-app comments keyed by app name, a single index
+	// comment summarizing all of them, and the VCS dependencies to post both.
+	mode        Mode
+	commenter   Commenter
+	vcs         toEmoji
+	appNoteIDs  map[string]int
+	indexNoteID int
+
+	// commentLock guards commenter I/O and appNoteIDs/indexNoteID, separate
+	// from m.lock, so posting/updating a per-app comment doesn't block
+	// AddNewApp/AddToAppMessage for the (potentially long) VCS round-trip.
+	commentLock sync.Mutex
+}
+
+// SetMode sets the comment layout mode. In ModePerApp, commenter is used to
+// post, update, and delete the individual app comments and the index comment,
+// and vcs to pick the emoji shown next to each app's worst state in the index.
+// Neither is used in ModeAggregated, which is the default.
+func (m *Message) SetMode(mode Mode, commenter Commenter, vcs toEmoji) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.mode = mode
+	m.commenter = commenter
+	m.vcs = vcs
 }
 
 func (m *Message) WorstState() pkg.CommitState {
@@ -93,6 +164,22 @@ func (m *Message) isDeleted(app string) bool {
 	return false
 }
 
+// appState returns app's worst recorded CheckResult state, or
+// pkg.StateNone if app has never been added. Callers must hold m.lock.
+func (m *Message) appState(app string) pkg.CommitState {
+	results, ok := m.apps[app]
+	if !ok {
+		return pkg.StateNone
+	}
+
+	state := pkg.StateSuccess
+	for _, check := range results.results {
+		state = pkg.WorstState(state, check.State)
+	}
+
+	return state
+}
+
 func (m *Message) AddNewApp(ctx context.Context, app string) {
 	if m.isDeleted(app) {
 		return
@@ -126,65 +213,379 @@ func init() {
 }
 
 func (m *Message) SetFooter(start time.Time, commitSHA, labelFilter string, showDebugInfo bool) {
+	duration := time.Since(start)
+
 	if !showDebugInfo {
 		m.footer = fmt.Sprintf("<small>_Done. CommitSHA: %s_<small>\n", commitSHA)
-		return
-	}
+	} else {
+		envStr := ""
+		if labelFilter != "" {
+			envStr = fmt.Sprintf(", Env: %s", labelFilter)
+		}
 
-	envStr := ""
-	if labelFilter != "" {
-		envStr = fmt.Sprintf(", Env: %s", labelFilter)
+		m.footer = fmt.Sprintf("<small>_Done: Pod: %s, Dur: %v, SHA: %s%s_<small>\n", hostname, duration, pkg.GitCommit, envStr)
 	}
-	duration := time.Since(start)
 
-	m.footer = fmt.Sprintf("<small>_Done: Pod: %s, Dur: %v, SHA: %s%s_<small>\n", hostname, duration, pkg.GitCommit, envStr)
+	if setter, ok := m.renderer.(MetadataSetter); ok {
+		setter.SetMetadata(m.footer, commitSHA, duration.Milliseconds(), hostname)
+	}
 }
 
 func (m *Message) BuildComment(ctx context.Context) string {
 	return m.buildComment(ctx)
 }
 
+// crossLock serializes acquiring two Messages' locks together for a cross-
+// Message comparison. Without it, a.Diff(b) and b.Diff(a) running
+// concurrently could each grab their own lock first and then deadlock
+// waiting on the other's; holding crossLock while taking both locks makes
+// that acquisition atomic instead.
+var crossLock sync.Mutex
+
+// lockPair locks m and, if previous is non-nil and distinct from m, previous
+// too, atomically with respect to other lockPair calls. The returned func
+// unlocks both.
+func (m *Message) lockPair(previous *Message) (unlock func()) {
+	crossLock.Lock()
+	m.lock.Lock()
+	if previous != nil && previous != m {
+		previous.lock.Lock()
+		crossLock.Unlock()
+		return func() {
+			previous.lock.Unlock()
+			m.lock.Unlock()
+		}
+	}
+
+	crossLock.Unlock()
+	return m.lock.Unlock
+}
+
+// Diff compares m against previous, a snapshot of the same PR/MR from an
+// earlier run, and returns the apps that are newly tracked, whose worst
+// state transitioned, and that are no longer present. previous may be nil,
+// in which case every current app counts as added.
+func (m *Message) Diff(previous *Message) (added, changed, removed []string) {
+	defer m.lockPair(previous)()
+
+	for name := range m.apps {
+		if m.isDeleted(name) {
+			continue
+		}
+
+		if previous == nil || !previous.hasApp(name) {
+			added = append(added, name)
+			continue
+		}
+
+		if previous.appState(name) != m.appState(name) {
+			changed = append(changed, name)
+		}
+	}
+
+	if previous != nil {
+		for name := range previous.apps {
+			if previous.isDeleted(name) || m.hasApp(name) {
+				continue
+			}
+			removed = append(removed, name)
+		}
+	}
+
+	slices.Sort(added)
+	slices.Sort(changed)
+	slices.Sort(removed)
+
+	return added, changed, removed
+}
+
+// hasApp reports whether app is currently tracked and not deleted. Callers
+// must hold m.lock.
+func (m *Message) hasApp(app string) bool {
+	_, ok := m.apps[app]
+	return ok && !m.isDeleted(app)
+}
+
+// RenderDelta builds a short "changes since last update" note listing only
+// the per-app state transitions between previous and m (new apps, state
+// transitions, and removals) rather than the full rollup. The VCS layer can
+// post this as a new comment - so subscribers get a notification - while
+// still editing the canonical rollup comment from BuildComment in place. It
+// returns "" when nothing changed.
+func (m *Message) RenderDelta(ctx context.Context, previous *Message) string {
+	_, span := otel.Tracer("Kubechecks").Start(ctx, "RenderDelta")
+	defer span.End()
+
+	added, changed, removed := m.Diff(previous)
+	if len(added) == 0 && len(changed) == 0 && len(removed) == 0 {
+		return ""
+	}
+
+	defer m.lockPair(previous)()
+
+	var sb strings.Builder
+	sb.WriteString("### Changes since last update\n")
+
+	for _, name := range added {
+		sb.WriteString(fmt.Sprintf("- `%s`: new → %s\n", name, m.appState(name).BareString()))
+	}
+	for _, name := range changed {
+		sb.WriteString(fmt.Sprintf("- `%s`: %s → %s\n", name, previous.appState(name).BareString(), m.appState(name).BareString()))
+	}
+	for _, name := range removed {
+		sb.WriteString(fmt.Sprintf("- `%s`: removed\n", name))
+	}
+
+	return sb.String()
+}
+
+// UpsertAppComment posts or updates app's individual comment and refreshes
+// the index comment so its summary/link stays current. It is a no-op unless
+// the message is in ModePerApp. The app's checks are snapshotted under
+// m.lock and rendered, but the commenter I/O runs under the separate
+// commentLock so it doesn't block AddToAppMessage/AddNewApp calls for other
+// apps while the VCS round-trip is in flight.
+func (m *Message) UpsertAppComment(ctx context.Context, app string) error {
+	_, span := otel.Tracer("Kubechecks").Start(ctx, "UpsertAppComment")
+	defer span.End()
+
+	m.lock.Lock()
+	mode := m.mode
+	commenter := m.commenter
+	deleted := m.isDeleted(app)
+	results, ok := m.apps[app]
+	var checks []CheckResult
+	var appState pkg.CommitState
+	if ok {
+		checks = append([]CheckResult(nil), results.results...)
+		appState = m.appState(app)
+	}
+	m.lock.Unlock()
+
+	if mode != ModePerApp || deleted || !ok {
+		return nil
+	}
+
+	body, err := m.renderer.Render(ctx, []RenderApp{{Name: app, State: appState, Checks: checks}})
+	if err != nil {
+		return fmt.Errorf("failed to render comment for app %q: %w", app, err)
+	}
+
+	m.commentLock.Lock()
+	defer m.commentLock.Unlock()
+
+	noteID, err := commenter.UpsertComment(ctx, m.appNoteIDs[app], body)
+	if err != nil {
+		return fmt.Errorf("failed to upsert comment for app %q: %w", app, err)
+	}
+	m.appNoteIDs[app] = noteID
+
+	return m.upsertIndexComment(ctx)
+}
+
+// RemoveAppComment deletes app's individual comment and refreshes the index
+// comment to drop its entry. It is a no-op unless the message is in
+// ModePerApp, or if app never had a comment posted. Like UpsertAppComment,
+// the commenter I/O runs under commentLock rather than m.lock.
+func (m *Message) RemoveAppComment(ctx context.Context, app string) error {
+	_, span := otel.Tracer("Kubechecks").Start(ctx, "RemoveAppComment")
+	defer span.End()
+
+	m.lock.Lock()
+	mode := m.mode
+	commenter := m.commenter
+	m.lock.Unlock()
+
+	if mode != ModePerApp {
+		return nil
+	}
+
+	m.commentLock.Lock()
+	defer m.commentLock.Unlock()
+
+	noteID, ok := m.appNoteIDs[app]
+	if !ok {
+		return nil
+	}
+
+	if err := commenter.DeleteComment(ctx, noteID); err != nil {
+		return fmt.Errorf("failed to delete comment for app %q: %w", app, err)
+	}
+	delete(m.appNoteIDs, app)
+
+	return m.upsertIndexComment(ctx)
+}
+
+// upsertIndexComment posts or updates the top-level comment listing every
+// app with a live per-app comment, alongside its worst state emoji and a
+// link to that app's comment. Callers must hold commentLock; it snapshots
+// m.apps/deletedAppsSet/m.vcs/m.commenter under m.lock before doing the
+// commenter I/O, so that I/O itself doesn't hold m.lock either.
+func (m *Message) upsertIndexComment(ctx context.Context) error {
+	names := getSortedKeys(m.appNoteIDs)
+
+	type indexEntry struct {
+		name   string
+		state  pkg.CommitState
+		noteID int
+	}
+
+	m.lock.Lock()
+	vcs := m.vcs
+	commenter := m.commenter
+	entries := make([]indexEntry, 0, len(names))
+	for _, name := range names {
+		if m.isDeleted(name) {
+			continue
+		}
+		entries = append(entries, indexEntry{name: name, state: m.appState(name), noteID: m.appNoteIDs[name]})
+	}
+	m.lock.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString("# Kubechecks Report\n\n")
+
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("- `%s` %s ([details](%s))\n", e.name, vcs.ToEmoji(e.state), commenter.CommentURL(e.noteID)))
+	}
+
+	noteID, err := commenter.UpsertComment(ctx, m.indexNoteID, sb.String())
+	if err != nil {
+		return fmt.Errorf("failed to upsert index comment: %w", err)
+	}
+	m.indexNoteID = noteID
+
+	return nil
+}
+
+// partHeaderFmt prefixes every part produced by BuildComments with a shared
+// title and a stable HTML-comment anchor, so a subsequent run can match each
+// rendered part back to the NoteID it was previously posted under.
+const partHeaderFmt = "# Kubechecks Report — part %d/%d\n<!-- kubechecks:part=%d -->\n"
+
+// partHeaderOverhead is a generous upper bound on partHeaderFmt's rendered
+// length (assuming at most 9999 parts), so the packing loop below can check
+// whether a candidate part will fit under maxBytes *after* the header is
+// prepended, not just the bare rendered body.
+var partHeaderOverhead = len(fmt.Sprintf(partHeaderFmt, 9999, 9999, 9999))
+
+// BuildComments partitions the message's apps across one or more comments,
+// keeping each app's rendered block intact, so that no part exceeds maxBytes
+// unless a single app's block alone does, in which case that app gets an
+// oversized part of its own. This keeps large reports under VCS body-size limits (GitHub's
+// 65536-char comment limit, GitLab's ~1MB note limit) on repos with many
+// Argo apps. PartNoteIDs is resized to match the returned slice, preserving
+// the overlapping prefix so a part's existing comment gets updated rather
+// than replaced; orphanedNoteIDs holds any trailing IDs that no longer have
+// a part (the part count shrank since the last run) so the caller can
+// delete those comments instead of abandoning them. Callers should
+// post/update each comment and persist the resulting IDs back into
+// PartNoteIDs before the next run.
+func (m *Message) BuildComments(ctx context.Context, maxBytes int) (comments []string, orphanedNoteIDs []int) {
+	_, span := otel.Tracer("Kubechecks").Start(ctx, "BuildComments")
+	defer span.End()
+
+	apps := m.renderApps()
+	if len(apps) == 0 {
+		return []string{fmt.Sprintf(partHeaderFmt, 1, 1, 1) + m.buildComment(ctx)}, m.resizePartNoteIDs(1)
+	}
+
+	var parts [][]RenderApp
+	var current []RenderApp
+
+	for _, app := range apps {
+		candidate := append(append([]RenderApp{}, current...), app)
+
+		rendered, err := m.renderer.Render(ctx, candidate)
+		fits := err == nil && len(rendered)+partHeaderOverhead <= maxBytes
+		if fits || len(current) == 0 {
+			// Always keep at least one app per part, even if its own
+			// block alone exceeds maxBytes — it can't be split further.
+			current = candidate
+			continue
+		}
+
+		parts = append(parts, current)
+		current = []RenderApp{app}
+	}
+	if len(current) > 0 {
+		parts = append(parts, current)
+	}
+
+	total := len(parts)
+	comments = make([]string, 0, total)
+	for i, part := range parts {
+		body, err := m.renderer.Render(ctx, part)
+		if err != nil {
+			body = fmt.Sprintf("failed to render comment: %v", err)
+		}
+
+		comments = append(comments, fmt.Sprintf(partHeaderFmt, i+1, total, i+1)+body)
+	}
+
+	return comments, m.resizePartNoteIDs(total)
+}
+
+// resizePartNoteIDs resizes PartNoteIDs to total entries, preserving the
+// overlapping prefix so existing parts keep their NoteID across a resize.
+// It returns any trailing IDs dropped because the part count shrank, so the
+// caller can delete those now-orphaned comments.
+func (m *Message) resizePartNoteIDs(total int) []int {
+	if len(m.PartNoteIDs) == total {
+		return nil
+	}
+
+	newIDs := make([]int, total)
+	n := copy(newIDs, m.PartNoteIDs)
+
+	var orphaned []int
+	if len(m.PartNoteIDs) > n {
+		orphaned = append([]int(nil), m.PartNoteIDs[n:]...)
+	}
+
+	m.PartNoteIDs = newIDs
+	return orphaned
+}
+
 // Iterate the map of all apps in this message, building a final comment from their current state
 func (m *Message) buildComment(ctx context.Context) string {
 	_, span := otel.Tracer("Kubechecks").Start(ctx, "buildComment")
 	defer span.End()
 
-	names := getSortedKeys(m.apps)
+	comment, err := m.renderer.Render(ctx, m.renderApps())
+	if err != nil {
+		return fmt.Sprintf("failed to render comment: %v", err)
+	}
 
-	var sb strings.Builder
-	sb.WriteString("# Kubechecks Report\n")
+	return comment
+}
+
+// renderApps snapshots the non-deleted apps, sorted by name, into the shape
+// a Renderer expects, computing each app's worst check state along the way.
+func (m *Message) renderApps() []RenderApp {
+	names := getSortedKeys(m.apps)
 
+	var apps []RenderApp
 	for _, appName := range names {
 		if m.isDeleted(appName) {
 			continue
 		}
 
-		var checkStrings []string
 		results := m.apps[appName]
 
 		appState := pkg.StateSuccess
 		for _, check := range results.results {
-			var summary string
-			if check.State == pkg.StateNone {
-				summary = check.Summary
-			} else {
-				summary = fmt.Sprintf("%s %s %s", check.Summary, check.State.BareString(), m.vcs.ToEmoji(check.State))
-			}
-
-			msg := fmt.Sprintf("<details>\n<summary>%s</summary>\n\n%s\n</details>", summary, check.Details)
-			checkStrings = append(checkStrings, msg)
 			appState = pkg.WorstState(appState, check.State)
 		}
 
-		sb.WriteString("<details>\n")
-		sb.WriteString("<summary>\n\n")
-		sb.WriteString(fmt.Sprintf("## ArgoCD Application Checks: `%s` %s\n", appName, m.vcs.ToEmoji(appState)))
-		sb.WriteString("</summary>\n\n")
-		sb.WriteString(strings.Join(checkStrings, "\n\n---\n\n"))
-		sb.WriteString("</details>")
+		apps = append(apps, RenderApp{
+			Name:   appName,
+			State:  appState,
+			Checks: results.results,
+		})
 	}
 
-	return sb.String()
+	return apps
 }
 
 func getSortedKeys[K constraints.Ordered, V any](m map[K]V) []K {
@@ -2,10 +2,14 @@ package msg
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/zapier/kubechecks/pkg"
 )
@@ -28,7 +32,7 @@ func TestBuildComment(t *testing.T) {
 			},
 		},
 	}
-	m := NewMessage("message", 1, 2, fakeEmojiable{":test:"})
+	m := NewMessage("message", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
 	m.apps = appResults
 	comment := m.buildComment(context.TODO())
 	assert.Equal(t, `# Kubechecks Report
@@ -48,7 +52,7 @@ should add some important details here
 func TestMessageIsSuccess(t *testing.T) {
 	t.Run("logic works", func(t *testing.T) {
 		var (
-			message = NewMessage("name", 1, 2, fakeEmojiable{":test:"})
+			message = NewMessage("name", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
 			ctx     = context.TODO()
 		)
 
@@ -94,7 +98,7 @@ func TestMessageIsSuccess(t *testing.T) {
 	for state := range testcases {
 		t.Run(state.BareString(), func(t *testing.T) {
 			var (
-				message = NewMessage("name", 1, 2, fakeEmojiable{":test:"})
+				message = NewMessage("name", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
 				ctx     = context.TODO()
 			)
 			message.AddNewApp(ctx, "some-app")
@@ -106,7 +110,7 @@ func TestMessageIsSuccess(t *testing.T) {
 
 func TestMultipleItemsWithNewlines(t *testing.T) {
 	var (
-		message = NewMessage("name", 1, 2, fakeEmojiable{":test:"})
+		message = NewMessage("name", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
 		ctx     = context.Background()
 	)
 	message.AddNewApp(ctx, "first-app")
@@ -160,4 +164,354 @@ func TestMultipleItemsWithNewlines(t *testing.T) {
 		assert.Equal(t, newline, result[index+3])
 		assert.Equal(t, newline, result[index+4])
 	}
+}
+
+func TestBuildCommentsFitsInOnePart(t *testing.T) {
+	message := NewMessage("name", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
+	ctx := context.Background()
+
+	message.AddNewApp(ctx, "first-app")
+	message.AddToAppMessage(ctx, "first-app", CheckResult{State: pkg.StateSuccess, Summary: "s", Details: "d"})
+
+	comments, orphaned := message.BuildComments(ctx, 1<<20)
+	assert.Len(t, comments, 1)
+	assert.Contains(t, comments[0], "part 1/1")
+	assert.Contains(t, comments[0], "first-app")
+	assert.Len(t, message.PartNoteIDs, 1)
+	assert.Empty(t, orphaned)
+}
+
+func TestBuildCommentsSplitsAcrossParts(t *testing.T) {
+	message := NewMessage("name", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
+	ctx := context.Background()
+
+	for _, name := range []string{"first-app", "second-app", "third-app"} {
+		message.AddNewApp(ctx, name)
+		message.AddToAppMessage(ctx, name, CheckResult{
+			State:   pkg.StateSuccess,
+			Summary: "s",
+			Details: strings.Repeat("d", 100),
+		})
+	}
+
+	comments, orphaned := message.BuildComments(ctx, 200)
+	assert.True(t, len(comments) > 1)
+	assert.Len(t, message.PartNoteIDs, len(comments))
+	assert.Empty(t, orphaned)
+
+	// each app must appear intact in exactly one part
+	for _, name := range []string{"first-app", "second-app", "third-app"} {
+		count := 0
+		for _, c := range comments {
+			if strings.Contains(c, name) {
+				count++
+			}
+		}
+		assert.Equal(t, 1, count)
+	}
+}
+
+func TestBuildCommentsResizesPartNoteIDsOnEmptyFastPath(t *testing.T) {
+	message := NewMessage("name", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
+	ctx := context.Background()
+
+	// simulate a previous run that had 3 parts, all apps since removed
+	message.PartNoteIDs = []int{111, 222, 333}
+
+	comments, orphaned := message.BuildComments(ctx, 1<<20)
+	assert.Len(t, comments, 1)
+	assert.Len(t, message.PartNoteIDs, 1)
+	// part 1 keeps its existing NoteID so the next post updates comment 111
+	// rather than creating a new one ...
+	assert.Equal(t, []int{111}, message.PartNoteIDs)
+	// ... and the now-unused parts 2/3 are surfaced so the caller can delete them.
+	assert.Equal(t, []int{222, 333}, orphaned)
+}
+
+func TestBuildCommentsPreservesPartNoteIDsPrefixOnShrink(t *testing.T) {
+	message := NewMessage("name", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
+	ctx := context.Background()
+
+	for _, name := range []string{"first-app", "second-app", "third-app"} {
+		message.AddNewApp(ctx, name)
+		message.AddToAppMessage(ctx, name, CheckResult{
+			State:   pkg.StateSuccess,
+			Summary: "s",
+			Details: strings.Repeat("d", 100),
+		})
+	}
+
+	comments, orphaned := message.BuildComments(ctx, 200)
+	require.True(t, len(comments) >= 2)
+	require.Empty(t, orphaned)
+
+	// simulate the caller persisting the posted comment IDs
+	for i := range message.PartNoteIDs {
+		message.PartNoteIDs[i] = 1000 + i
+	}
+	previousIDs := append([]int(nil), message.PartNoteIDs...)
+
+	// remove apps so a rerun needs fewer parts
+	message.RemoveApp("second-app")
+	message.RemoveApp("third-app")
+
+	_, orphaned = message.BuildComments(ctx, 1<<20)
+	assert.Equal(t, []int{previousIDs[0]}, message.PartNoteIDs)
+	assert.Equal(t, previousIDs[1:], orphaned)
+}
+
+func TestBuildCommentsPreservesPartNoteIDsPrefixOnGrowth(t *testing.T) {
+	message := NewMessage("name", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
+	ctx := context.Background()
+
+	message.AddNewApp(ctx, "first-app")
+	message.AddToAppMessage(ctx, "first-app", CheckResult{State: pkg.StateSuccess, Summary: "s", Details: "d"})
+
+	_, orphaned := message.BuildComments(ctx, 1<<20)
+	require.Empty(t, orphaned)
+	message.PartNoteIDs[0] = 1000
+
+	for _, name := range []string{"second-app", "third-app"} {
+		message.AddNewApp(ctx, name)
+		message.AddToAppMessage(ctx, name, CheckResult{
+			State:   pkg.StateSuccess,
+			Summary: "s",
+			Details: strings.Repeat("d", 100),
+		})
+	}
+
+	_, orphaned = message.BuildComments(ctx, 200)
+	require.True(t, len(message.PartNoteIDs) >= 2)
+	assert.Equal(t, 1000, message.PartNoteIDs[0])
+	assert.Empty(t, orphaned)
+}
+
+func TestBuildCommentsAccountsForPartHeaderInFitsCheck(t *testing.T) {
+	renderer := NewMarkdownRenderer(fakeEmojiable{":test:"})
+	message := NewMessage("name", 1, 2, renderer)
+	ctx := context.Background()
+
+	message.AddNewApp(ctx, "first-app")
+	message.AddToAppMessage(ctx, "first-app", CheckResult{State: pkg.StateSuccess, Summary: "s", Details: "d"})
+	message.AddNewApp(ctx, "second-app")
+	message.AddToAppMessage(ctx, "second-app", CheckResult{State: pkg.StateSuccess, Summary: "s", Details: "d"})
+
+	combined, err := renderer.Render(ctx, message.renderApps())
+	assert.NoError(t, err)
+
+	// a budget exactly equal to the combined bare render leaves no room for
+	// the part header, so the two apps must not be packed into a single
+	// part (which would then exceed maxBytes once the header is added).
+	comments, _ := message.BuildComments(ctx, len(combined))
+	assert.Len(t, comments, 2)
+	for _, c := range comments {
+		assert.LessOrEqual(t, len(c), len(combined))
+	}
+}
+
+type fakeCommenter struct {
+	nextID   int
+	comments map[int]string
+}
+
+func newFakeCommenter() *fakeCommenter {
+	return &fakeCommenter{comments: make(map[int]string)}
+}
+
+func (fc *fakeCommenter) UpsertComment(ctx context.Context, noteID int, body string) (int, error) {
+	if noteID == 0 {
+		fc.nextID++
+		noteID = fc.nextID
+	}
+	fc.comments[noteID] = body
+	return noteID, nil
+}
+
+func (fc *fakeCommenter) DeleteComment(ctx context.Context, noteID int) error {
+	delete(fc.comments, noteID)
+	return nil
+}
+
+func (fc *fakeCommenter) CommentURL(noteID int) string {
+	return fmt.Sprintf("https://example.invalid/pr/1#note-%d", noteID)
+}
+
+func TestPerAppComments(t *testing.T) {
+	commenter := newFakeCommenter()
+	message := NewMessage("name", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
+	message.SetMode(ModePerApp, commenter, fakeEmojiable{":test:"})
+	ctx := context.Background()
+
+	message.AddNewApp(ctx, "first-app")
+	message.AddToAppMessage(ctx, "first-app", CheckResult{State: pkg.StateSuccess, Summary: "s", Details: "d"})
+	assert.NoError(t, message.UpsertAppComment(ctx, "first-app"))
+
+	message.AddNewApp(ctx, "second-app")
+	message.AddToAppMessage(ctx, "second-app", CheckResult{State: pkg.StateFailure, Summary: "s", Details: "d"})
+	assert.NoError(t, message.UpsertAppComment(ctx, "second-app"))
+
+	// two app comments plus the index comment
+	assert.Len(t, commenter.comments, 3)
+	assert.Contains(t, commenter.comments[message.indexNoteID], "first-app")
+	assert.Contains(t, commenter.comments[message.indexNoteID], "second-app")
+	assert.Contains(t, commenter.comments[message.indexNoteID], commenter.CommentURL(message.appNoteIDs["first-app"]))
+
+	assert.NoError(t, message.RemoveAppComment(ctx, "first-app"))
+	assert.Len(t, commenter.comments, 2)
+	assert.NotContains(t, commenter.comments[message.indexNoteID], "first-app")
+	assert.Contains(t, commenter.comments[message.indexNoteID], "second-app")
+}
+
+func TestDiffAndRenderDelta(t *testing.T) {
+	ctx := context.Background()
+	renderer := NewMarkdownRenderer(fakeEmojiable{":test:"})
+
+	previous := NewMessage("name", 1, 2, renderer)
+	previous.AddNewApp(ctx, "stable-app")
+	previous.AddToAppMessage(ctx, "stable-app", CheckResult{State: pkg.StateSuccess})
+	previous.AddNewApp(ctx, "flaky-app")
+	previous.AddToAppMessage(ctx, "flaky-app", CheckResult{State: pkg.StateRunning})
+	previous.AddNewApp(ctx, "gone-app")
+	previous.AddToAppMessage(ctx, "gone-app", CheckResult{State: pkg.StateSuccess})
+
+	current := NewMessage("name", 1, 2, renderer)
+	current.AddNewApp(ctx, "stable-app")
+	current.AddToAppMessage(ctx, "stable-app", CheckResult{State: pkg.StateSuccess})
+	current.AddNewApp(ctx, "flaky-app")
+	current.AddToAppMessage(ctx, "flaky-app", CheckResult{State: pkg.StateFailure})
+	current.AddNewApp(ctx, "new-app")
+	current.AddToAppMessage(ctx, "new-app", CheckResult{State: pkg.StateSuccess})
+
+	added, changed, removed := current.Diff(previous)
+	assert.Equal(t, []string{"new-app"}, added)
+	assert.Equal(t, []string{"flaky-app"}, changed)
+	assert.Equal(t, []string{"gone-app"}, removed)
+
+	delta := current.RenderDelta(ctx, previous)
+	assert.Contains(t, delta, "### Changes since last update")
+	assert.Contains(t, delta, "`new-app`: new → Success")
+	assert.Contains(t, delta, "`flaky-app`: Running → Failure")
+	assert.Contains(t, delta, "`gone-app`: removed")
+	assert.NotContains(t, delta, "stable-app")
+}
+
+func TestDiffNoPrevious(t *testing.T) {
+	ctx := context.Background()
+	current := NewMessage("name", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
+	current.AddNewApp(ctx, "first-app")
+	current.AddToAppMessage(ctx, "first-app", CheckResult{State: pkg.StateSuccess})
+
+	added, changed, removed := current.Diff(nil)
+	assert.Equal(t, []string{"first-app"}, added)
+	assert.Empty(t, changed)
+	assert.Empty(t, removed)
+}
+
+func TestRenderDeltaNoChanges(t *testing.T) {
+	ctx := context.Background()
+	renderer := NewMarkdownRenderer(fakeEmojiable{":test:"})
+
+	previous := NewMessage("name", 1, 2, renderer)
+	previous.AddNewApp(ctx, "first-app")
+	previous.AddToAppMessage(ctx, "first-app", CheckResult{State: pkg.StateSuccess})
+
+	current := NewMessage("name", 1, 2, renderer)
+	current.AddNewApp(ctx, "first-app")
+	current.AddToAppMessage(ctx, "first-app", CheckResult{State: pkg.StateSuccess})
+
+	assert.Empty(t, current.RenderDelta(ctx, previous))
+}
+
+func TestDiffConcurrentCrossMessageNoDeadlock(t *testing.T) {
+	renderer := NewMarkdownRenderer(fakeEmojiable{":test:"})
+	ctx := context.Background()
+
+	a := NewMessage("a", 1, 1, renderer)
+	a.AddNewApp(ctx, "app")
+	a.AddToAppMessage(ctx, "app", CheckResult{State: pkg.StateSuccess})
+
+	b := NewMessage("b", 2, 2, renderer)
+	b.AddNewApp(ctx, "app")
+	b.AddToAppMessage(ctx, "app", CheckResult{State: pkg.StateFailure})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); a.Diff(b) }()
+		go func() { defer wg.Done(); b.Diff(a) }()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a.Diff(b) and b.Diff(a) deadlocked under concurrent access")
+	}
+}
+
+// slowCommenter simulates a real VCS round-trip, so tests can tell whether
+// unrelated Message methods are blocked behind it.
+type slowCommenter struct {
+	*fakeCommenter
+	delay time.Duration
+}
+
+func (sc *slowCommenter) UpsertComment(ctx context.Context, noteID int, body string) (int, error) {
+	time.Sleep(sc.delay)
+	return sc.fakeCommenter.UpsertComment(ctx, noteID, body)
+}
+
+func TestUpsertAppCommentSeesModeUnderLock(t *testing.T) {
+	commenter := newFakeCommenter()
+	message := NewMessage("name", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
+	ctx := context.Background()
+
+	message.AddNewApp(ctx, "first-app")
+	message.AddToAppMessage(ctx, "first-app", CheckResult{State: pkg.StateSuccess, Summary: "s", Details: "d"})
+
+	// SetMode and UpsertAppComment both touch mode/commenter/vcs; running
+	// them concurrently must not race (go test -race would catch a plain
+	// unsynchronized read/write here).
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		message.SetMode(ModePerApp, commenter, fakeEmojiable{":test:"})
+	}()
+	go func() {
+		defer wg.Done()
+		_ = message.UpsertAppComment(ctx, "first-app")
+	}()
+	wg.Wait()
+}
+
+func TestUpsertAppCommentDoesNotBlockAddToAppMessage(t *testing.T) {
+	commenter := &slowCommenter{fakeCommenter: newFakeCommenter(), delay: 200 * time.Millisecond}
+	message := NewMessage("name", 1, 2, NewMarkdownRenderer(fakeEmojiable{":test:"}))
+	message.SetMode(ModePerApp, commenter, fakeEmojiable{":test:"})
+	ctx := context.Background()
+
+	message.AddNewApp(ctx, "first-app")
+	message.AddToAppMessage(ctx, "first-app", CheckResult{State: pkg.StateSuccess, Summary: "s", Details: "d"})
+
+	go message.UpsertAppComment(ctx, "first-app")
+	time.Sleep(20 * time.Millisecond) // let UpsertAppComment enter its commenter round-trip
+
+	done := make(chan struct{})
+	go func() {
+		message.AddNewApp(ctx, "second-app")
+		message.AddToAppMessage(ctx, "second-app", CheckResult{State: pkg.StateSuccess})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("AddToAppMessage blocked behind an in-flight commenter round-trip")
+	}
 }
\ No newline at end of file
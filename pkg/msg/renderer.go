@@ -0,0 +1,316 @@
+package msg
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/zapier/kubechecks/pkg"
+)
+
+// RenderApp is the renderer-facing view of a single ArgoCD application's
+// check results: its name, its worst check state, and the checks themselves.
+type RenderApp struct {
+	Name   string
+	State  pkg.CommitState
+	Checks []CheckResult
+}
+
+// Renderer turns a set of per-app check results into a comment body. Callers
+// choose a Renderer based on where the output is headed: a PR/MR comment, an
+// artifact upload, or a checks API.
+type Renderer interface {
+	Render(ctx context.Context, apps []RenderApp) (string, error)
+}
+
+// MetadataSetter is an optional interface a Renderer can implement to accept
+// run-level metadata (footer text, commit SHA, run duration, hostname)
+// before Render is called. Message.SetFooter calls this if the configured
+// Renderer implements it, so e.g. TemplateRenderer can expose the footer to
+// user-supplied templates. MarkdownRenderer does not implement it and does
+// not render a footer.
+type MetadataSetter interface {
+	SetMetadata(footer, commitSHA string, durationMs int64, hostname string)
+}
+
+// MarkdownRenderer renders results as the collapsible GitHub/GitLab markdown
+// comment kubechecks has always produced. It is the default Renderer.
+type MarkdownRenderer struct {
+	vcs toEmoji
+}
+
+func NewMarkdownRenderer(vcs toEmoji) *MarkdownRenderer {
+	return &MarkdownRenderer{vcs: vcs}
+}
+
+func (r *MarkdownRenderer) Render(ctx context.Context, apps []RenderApp) (string, error) {
+	_, span := otel.Tracer("Kubechecks").Start(ctx, "MarkdownRenderer.Render")
+	defer span.End()
+
+	var sb strings.Builder
+	sb.WriteString("# Kubechecks Report\n")
+
+	for _, app := range apps {
+		var checkStrings []string
+		for _, check := range app.Checks {
+			var summary string
+			if check.State == pkg.StateNone {
+				summary = check.Summary
+			} else {
+				summary = fmt.Sprintf("%s %s %s", check.Summary, check.State.BareString(), r.vcs.ToEmoji(check.State))
+			}
+
+			msg := fmt.Sprintf("<details>\n<summary>%s</summary>\n\n%s\n</details>", summary, check.Details)
+			checkStrings = append(checkStrings, msg)
+		}
+
+		sb.WriteString("<details>\n")
+		sb.WriteString("<summary>\n\n")
+		sb.WriteString(fmt.Sprintf("## ArgoCD Application Checks: `%s` %s\n", app.Name, r.vcs.ToEmoji(app.State)))
+		sb.WriteString("</summary>\n\n")
+		sb.WriteString(strings.Join(checkStrings, "\n\n---\n\n"))
+		sb.WriteString("</details>")
+	}
+
+	return sb.String(), nil
+}
+
+// JSONRenderer serializes results for machine consumption, e.g. artifact
+// upload or piping into another tool.
+type JSONRenderer struct{}
+
+func NewJSONRenderer() *JSONRenderer {
+	return &JSONRenderer{}
+}
+
+type jsonCheckResult struct {
+	State   string `json:"state"`
+	Summary string `json:"summary"`
+	Details string `json:"details"`
+}
+
+type jsonApp struct {
+	Name   string            `json:"name"`
+	State  string            `json:"state"`
+	Checks []jsonCheckResult `json:"checks"`
+}
+
+func (r *JSONRenderer) Render(ctx context.Context, apps []RenderApp) (string, error) {
+	_, span := otel.Tracer("Kubechecks").Start(ctx, "JSONRenderer.Render")
+	defer span.End()
+
+	out := make([]jsonApp, 0, len(apps))
+	for _, app := range apps {
+		checks := make([]jsonCheckResult, 0, len(app.Checks))
+		for _, check := range app.Checks {
+			checks = append(checks, jsonCheckResult{
+				State:   check.State.BareString(),
+				Summary: check.Summary,
+				Details: check.Details,
+			})
+		}
+
+		out = append(out, jsonApp{
+			Name:   app.Name,
+			State:  app.State.BareString(),
+			Checks: checks,
+		})
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON report: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// JUnitRenderer serializes results as JUnit XML, so CI systems can display
+// each app's checks as test cases.
+type JUnitRenderer struct{}
+
+func NewJUnitRenderer() *JUnitRenderer {
+	return &JUnitRenderer{}
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+func (r *JUnitRenderer) Render(ctx context.Context, apps []RenderApp) (string, error) {
+	_, span := otel.Tracer("Kubechecks").Start(ctx, "JUnitRenderer.Render")
+	defer span.End()
+
+	suites := junitTestSuites{Suites: make([]junitTestSuite, 0, len(apps))}
+
+	for _, app := range apps {
+		suite := junitTestSuite{
+			Name:      app.Name,
+			Tests:     len(app.Checks),
+			TestCases: make([]junitTestCase, 0, len(app.Checks)),
+		}
+
+		for _, check := range app.Checks {
+			tc := junitTestCase{Name: check.Summary}
+			if isFailingState(check.State) {
+				suite.Failures++
+				tc.Failure = &junitFailure{
+					Message: check.State.BareString(),
+					Content: check.Details,
+				}
+			}
+			suite.TestCases = append(suite.TestCases, tc)
+		}
+
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+
+	return xml.Header + string(data), nil
+}
+
+// SARIFRenderer serializes results as SARIF v2.1.0, so Argo/kustomize/
+// kubeconform findings can be surfaced in GitHub's code-scanning UI.
+type SARIFRenderer struct {
+	// ToolName identifies the producing tool in the SARIF "driver" block.
+	ToolName string
+}
+
+func NewSARIFRenderer(toolName string) *SARIFRenderer {
+	return &SARIFRenderer{ToolName: toolName}
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+func (r *SARIFRenderer) Render(ctx context.Context, apps []RenderApp) (string, error) {
+	_, span := otel.Tracer("Kubechecks").Start(ctx, "SARIFRenderer.Render")
+	defer span.End()
+
+	toolName := r.ToolName
+	if toolName == "" {
+		toolName = "kubechecks"
+	}
+
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: toolName}},
+		Results: []sarifResult{},
+	}
+
+	for _, app := range apps {
+		for _, check := range app.Checks {
+			if !isFailingState(check.State) && check.State != pkg.StateWarning {
+				continue
+			}
+
+			run.Results = append(run.Results, sarifResult{
+				RuleID:  check.Summary,
+				Level:   sarifLevel(check.State),
+				Message: sarifMessage{Text: check.Details},
+				Locations: []sarifLocation{
+					{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: app.Name}}},
+				},
+			})
+		}
+	}
+
+	out := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal SARIF report: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func sarifLevel(state pkg.CommitState) string {
+	switch {
+	case isFailingState(state):
+		return "error"
+	case state == pkg.StateWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// isFailingState reports whether a check's state should count as a failure
+// for reporting purposes, rather than an in-progress or successful state.
+func isFailingState(state pkg.CommitState) bool {
+	switch state {
+	case pkg.StateFailure, pkg.StateError, pkg.StatePanic:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,66 @@
+package msg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zapier/kubechecks/pkg"
+)
+
+func testApps() []RenderApp {
+	return []RenderApp{
+		{
+			Name:  "myapp",
+			State: pkg.StateError,
+			Checks: []CheckResult{
+				{
+					State:   pkg.StateError,
+					Summary: "this failed bigly",
+					Details: "should add some important details here",
+				},
+				{
+					State:   pkg.StateSuccess,
+					Summary: "this one passed",
+					Details: "all good",
+				},
+			},
+		},
+	}
+}
+
+func TestMarkdownRenderer(t *testing.T) {
+	r := NewMarkdownRenderer(fakeEmojiable{":test:"})
+	out, err := r.Render(context.TODO(), testApps())
+	assert.NoError(t, err)
+	assert.Contains(t, out, "# Kubechecks Report")
+	assert.Contains(t, out, "`myapp`")
+	assert.Contains(t, out, "this failed bigly Error :test:")
+}
+
+func TestJSONRenderer(t *testing.T) {
+	r := NewJSONRenderer()
+	out, err := r.Render(context.TODO(), testApps())
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"name": "myapp"`)
+	assert.Contains(t, out, `"summary": "this failed bigly"`)
+}
+
+func TestJUnitRenderer(t *testing.T) {
+	r := NewJUnitRenderer()
+	out, err := r.Render(context.TODO(), testApps())
+	assert.NoError(t, err)
+	assert.Contains(t, out, `<testsuite name="myapp" tests="2" failures="1">`)
+	assert.Contains(t, out, `<failure message="Error">should add some important details here</failure>`)
+}
+
+func TestSARIFRenderer(t *testing.T) {
+	r := NewSARIFRenderer("kubechecks")
+	out, err := r.Render(context.TODO(), testApps())
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"version": "2.1.0"`)
+	assert.Contains(t, out, `"ruleId": "this failed bigly"`)
+	assert.Contains(t, out, `"level": "error"`)
+	assert.NotContains(t, out, "this one passed")
+}
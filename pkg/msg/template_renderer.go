@@ -0,0 +1,222 @@
+package msg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"text/template"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/zapier/kubechecks/pkg"
+)
+
+// DefaultCommentTemplate is the Markdown layout kubechecks has always
+// shipped, expressed as a text/template so operators can override the whole
+// report - including the top-level banner, not just the per-app blocks -
+// via config without patching the binary.
+const DefaultCommentTemplate = `# Kubechecks Report
+{{ range .Apps }}<details>
+<summary>
+
+## ArgoCD Application Checks: ` + "`{{ .AppName }}`" + ` {{ .ToEmoji .AppState }}
+</summary>
+
+{{ range $i, $check := .Checks }}{{ if $i }}
+
+---
+
+{{ end }}{{ $check.Rendered }}{{ end }}</details>{{ end }}`
+
+// defaultCheckTemplateText reproduces kubechecks' original inline rendering
+// for a single check: a bare summary when the check carries no state,
+// otherwise the summary suffixed with the state name and its emoji.
+const defaultCheckTemplateText = `<details>
+<summary>{{ .Summary }}{{ if not .IsNone }} {{ .State.BareString }} {{ .ToEmoji }}{{ end }}</summary>
+
+{{ .Details }}
+</details>`
+
+var defaultCheckTmpl = template.Must(template.New("default-check").Parse(defaultCheckTemplateText))
+
+// CheckTemplateContext is what a per-kind check template is rendered
+// against.
+type CheckTemplateContext struct {
+	Summary string
+	Details string
+	State   pkg.CommitState
+
+	vcs toEmoji
+}
+
+func (c CheckTemplateContext) IsNone() bool { return c.State == pkg.StateNone }
+func (c CheckTemplateContext) ToEmoji() string {
+	return c.vcs.ToEmoji(c.State)
+}
+
+// TemplateCheck is a single check as seen by the app-level template: the
+// same data as CheckTemplateContext, plus the check's Kind and Rendered, its
+// already-rendered form (via the kind-specific template, or the default one).
+type TemplateCheck struct {
+	Summary  string
+	Details  string
+	State    pkg.CommitState
+	Kind     string
+	Rendered string
+}
+
+// TemplateContext is a single app as seen by the report-level template,
+// inside its .Apps range.
+type TemplateContext struct {
+	AppName  string
+	AppState pkg.CommitState
+	Checks   []TemplateCheck
+
+	vcs toEmoji
+}
+
+func (tc TemplateContext) ToEmoji(state pkg.CommitState) string {
+	return tc.vcs.ToEmoji(state)
+}
+
+// ReportTemplateContext is what the top-level report template
+// (DefaultCommentTemplate, or an operator-supplied override) is rendered
+// against: every app plus run-level metadata, so operators can customize
+// the whole comment layout - banner included - without patching the binary.
+type ReportTemplateContext struct {
+	Apps []TemplateContext
+
+	Footer     string
+	CommitSHA  string
+	DurationMs int64
+	Hostname   string
+}
+
+// TemplateRenderer renders comments from a user-supplied report-level
+// text/template, falling back to DefaultCommentTemplate. Checks can
+// additionally be routed to a per-kind template via RegisterCheckTemplate,
+// so e.g. kubeconform results can render as a table while preupgrade
+// results render as a diff.
+type TemplateRenderer struct {
+	vcs  toEmoji
+	tmpl *template.Template
+
+	checkTmplsMu sync.RWMutex
+	checkTmpls   map[string]*template.Template
+
+	// Footer, CommitSHA, DurationMs, and Hostname are exposed to templates
+	// alongside the per-app fields. Callers set them before Render.
+	Footer     string
+	CommitSHA  string
+	DurationMs int64
+	Hostname   string
+}
+
+func NewTemplateRenderer(vcs toEmoji, tmplText string) (*TemplateRenderer, error) {
+	if tmplText == "" {
+		tmplText = DefaultCommentTemplate
+	}
+
+	tmpl, err := template.New("report").Parse(tmplText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse comment template: %w", err)
+	}
+
+	return &TemplateRenderer{
+		vcs:        vcs,
+		tmpl:       tmpl,
+		checkTmpls: make(map[string]*template.Template),
+		Hostname:   hostname,
+	}, nil
+}
+
+// SetMetadata implements MetadataSetter, so Message.SetFooter can populate
+// the Footer/CommitSHA/DurationMs/Hostname fields exposed to templates.
+func (r *TemplateRenderer) SetMetadata(footer, commitSHA string, durationMs int64, hostname string) {
+	r.Footer = footer
+	r.CommitSHA = commitSHA
+	r.DurationMs = durationMs
+	r.Hostname = hostname
+}
+
+// RegisterCheckTemplate overrides how checks of the given kind are rendered.
+// Checks whose Kind has no registered template fall back to the default
+// inline <details> block.
+func (r *TemplateRenderer) RegisterCheckTemplate(kind, tmplText string) error {
+	tmpl, err := template.New(kind).Parse(tmplText)
+	if err != nil {
+		return fmt.Errorf("failed to parse check template for kind %q: %w", kind, err)
+	}
+
+	r.checkTmplsMu.Lock()
+	defer r.checkTmplsMu.Unlock()
+	r.checkTmpls[kind] = tmpl
+
+	return nil
+}
+
+func (r *TemplateRenderer) renderCheck(check CheckResult) (string, error) {
+	tctx := CheckTemplateContext{Summary: check.Summary, Details: check.Details, State: check.State, vcs: r.vcs}
+
+	r.checkTmplsMu.RLock()
+	tmpl, ok := r.checkTmpls[check.Kind]
+	r.checkTmplsMu.RUnlock()
+
+	if !ok {
+		tmpl = defaultCheckTmpl
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, tctx); err != nil {
+		return "", fmt.Errorf("failed to render check %q: %w", check.Summary, err)
+	}
+
+	return buf.String(), nil
+}
+
+func (r *TemplateRenderer) Render(ctx context.Context, apps []RenderApp) (string, error) {
+	_, span := otel.Tracer("Kubechecks").Start(ctx, "TemplateRenderer.Render")
+	defer span.End()
+
+	appCtxs := make([]TemplateContext, 0, len(apps))
+	for _, app := range apps {
+		checks := make([]TemplateCheck, 0, len(app.Checks))
+		for _, check := range app.Checks {
+			rendered, err := r.renderCheck(check)
+			if err != nil {
+				return "", err
+			}
+
+			checks = append(checks, TemplateCheck{
+				Summary:  check.Summary,
+				Details:  check.Details,
+				State:    check.State,
+				Kind:     check.Kind,
+				Rendered: rendered,
+			})
+		}
+
+		appCtxs = append(appCtxs, TemplateContext{
+			AppName:  app.Name,
+			AppState: app.State,
+			Checks:   checks,
+			vcs:      r.vcs,
+		})
+	}
+
+	rctx := ReportTemplateContext{
+		Apps:       appCtxs,
+		Footer:     r.Footer,
+		CommitSHA:  r.CommitSHA,
+		DurationMs: r.DurationMs,
+		Hostname:   r.Hostname,
+	}
+
+	var buf bytes.Buffer
+	if err := r.tmpl.Execute(&buf, rctx); err != nil {
+		return "", fmt.Errorf("failed to render report: %w", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,82 @@
+package msg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zapier/kubechecks/pkg"
+)
+
+func TestTemplateRendererDefaultTemplate(t *testing.T) {
+	r, err := NewTemplateRenderer(fakeEmojiable{":test:"}, "")
+	require.NoError(t, err)
+
+	out, err := r.Render(context.TODO(), testApps())
+	assert.NoError(t, err)
+	assert.Contains(t, out, "# Kubechecks Report")
+	assert.Contains(t, out, "`myapp`")
+	assert.Contains(t, out, "this failed bigly Error :test:")
+	assert.Contains(t, out, "this one passed Success :test:</summary>")
+}
+
+func TestTemplateRendererCustomTemplate(t *testing.T) {
+	r, err := NewTemplateRenderer(fakeEmojiable{":test:"}, "{{ range .Apps }}App: {{ .AppName }} ({{ len .Checks }} checks)\n{{ end }}")
+	require.NoError(t, err)
+
+	out, err := r.Render(context.TODO(), testApps())
+	assert.NoError(t, err)
+	assert.Equal(t, "App: myapp (2 checks)\n", out)
+}
+
+func TestTemplateRendererCustomTemplateOverridesBanner(t *testing.T) {
+	r, err := NewTemplateRenderer(fakeEmojiable{":test:"}, "# Acme Cluster Report\n{{ range .Apps }}{{ .AppName }}\n{{ end }}")
+	require.NoError(t, err)
+
+	out, err := r.Render(context.TODO(), testApps())
+	assert.NoError(t, err)
+	assert.Equal(t, "# Acme Cluster Report\nmyapp\n", out)
+	assert.NotContains(t, out, "# Kubechecks Report")
+}
+
+func TestTemplateRendererPerKindCheckTemplate(t *testing.T) {
+	r, err := NewTemplateRenderer(fakeEmojiable{":test:"}, "")
+	require.NoError(t, err)
+	require.NoError(t, r.RegisterCheckTemplate("kubeconform", "KUBECONFORM: {{ .Summary }}"))
+
+	apps := []RenderApp{
+		{
+			Name:  "myapp",
+			State: pkg.StateFailure,
+			Checks: []CheckResult{
+				{State: pkg.StateFailure, Summary: "schema invalid", Kind: "kubeconform"},
+				{State: pkg.StateSuccess, Summary: "other check"},
+			},
+		},
+	}
+
+	out, err := r.Render(context.TODO(), apps)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "KUBECONFORM: schema invalid")
+	assert.Contains(t, out, "<summary>other check Success :test:</summary>")
+}
+
+func TestMessageSetFooterPopulatesTemplateRendererMetadata(t *testing.T) {
+	r, err := NewTemplateRenderer(fakeEmojiable{":test:"}, "{{ .Footer }}|{{ .CommitSHA }}|{{ .Hostname }}\n")
+	require.NoError(t, err)
+
+	m := NewMessage("message", 1, 2, r)
+	start := time.Now().Add(-time.Second)
+	m.SetFooter(start, "abc123", "", false)
+
+	assert.Equal(t, "abc123", r.CommitSHA)
+	assert.NotEmpty(t, r.Hostname)
+	assert.Contains(t, r.Footer, "abc123")
+
+	out, err := r.Render(context.TODO(), testApps())
+	assert.NoError(t, err)
+	assert.Contains(t, out, r.Footer+"|abc123|"+r.Hostname)
+}